@@ -0,0 +1,166 @@
+package restic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"reflect"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// CopyProgressEntry mirrors the JSON lines `restic copy --json` emits
+// while transferring snapshots between repositories.
+type CopyProgressEntry struct {
+	MessageType string `json:"message_type"` // "status" or "summary"
+
+	SnapshotID string `json:"snapshot_id,omitempty"`
+	Done       bool   `json:"done,omitempty"`
+}
+
+type CopyOpts struct {
+	extraArgs []string
+}
+
+type CopyOption func(opts *CopyOpts)
+
+func WithCopyHost(host string) CopyOption {
+	return func(opts *CopyOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--host", host)
+	}
+}
+
+func WithCopyTags(tags ...string) CopyOption {
+	return func(opts *CopyOpts) {
+		for _, tag := range tags {
+			opts.extraArgs = append(opts.extraArgs, "--tag", tag)
+		}
+	}
+}
+
+// CopyTo transfers the given snapshots (or all snapshots matching the
+// filter options, if snapshotIDs is empty) from r into dst, equivalent to
+// `restic copy --repo2 ...`. Progress entries are streamed to
+// progressCallback following the same pipe + goroutine + multierror
+// pattern as Backup.
+func (r *Repo) CopyTo(ctx context.Context, dst *Repo, snapshotIDs []string, progressCallback func(*CopyProgressEntry), opts ...CopyOption) error {
+	unlock := lockRepoPair(r, dst)
+	defer unlock()
+
+	if err := r.init(ctx); err != nil {
+		return fmt.Errorf("failed to initialize repo: %w", err)
+	}
+
+	opt := &CopyOpts{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	args := []string{"copy", "--json"}
+	args = append(args, r.extraArgs...)
+	args = append(args, opt.extraArgs...)
+	args = append(args, snapshotIDs...)
+
+	env := append([]string{}, r.buildEnv()...)
+	env = append(env,
+		"RESTIC_REPOSITORY2="+dst.repo.GetUri(),
+		"RESTIC_PASSWORD2="+dst.repo.GetPassword(),
+	)
+	env = append(env, dst.repo.GetEnv()...)
+
+	reader, writer := io.Pipe()
+
+	// A single `restic copy` process holds a lock on both the source and
+	// destination repositories, so both are watched for staleness; either
+	// one going stale cancels the shared lockCtx.
+	lockCtx, lockCancel := r.watchLock(ctx)
+
+	cmd := exec.CommandContext(lockCtx, r.cmd, args...)
+	cmd.Env = append(cmd.Env, env...)
+	cmd.Stderr = writer
+	cmd.Stdout = writer
+
+	if err := cmd.Start(); err != nil {
+		lockCancel()
+		return NewCmdError(cmd, nil, err)
+	}
+
+	finishSrcLockWatch := r.startLockWatchdog(lockCtx, lockCancel, cmd.Process.Pid)
+	finishDstLockWatch := dst.startLockWatchdog(lockCtx, lockCancel, cmd.Process.Pid)
+
+	var wg sync.WaitGroup
+	var cmdErr error
+	var readErr error
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := readCopyProgressEntries(reader, progressCallback); err != nil {
+			readErr = fmt.Errorf("processing command output: %w", err)
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer writer.Close()
+		defer wg.Done()
+		if err := cmd.Wait(); err != nil {
+			err = finishSrcLockWatch(err)
+			err = finishDstLockWatch(err)
+			cmdErr = NewCmdError(cmd, nil, err)
+		} else {
+			finishSrcLockWatch(nil)
+			finishDstLockWatch(nil)
+		}
+	}()
+
+	wg.Wait()
+
+	if cmdErr != nil || readErr != nil {
+		return multierror.Append(nil, cmdErr, readErr)
+	}
+	return nil
+}
+
+// lockRepoPair locks both a.mu and b.mu (or just a.mu if a and b are the
+// same Repo), always in pointer-address order, so that a.CopyTo(ctx, b,
+// ...) racing b.CopyTo(ctx, a, ...) can't deadlock. The returned unlock
+// func releases the locks in reverse order.
+func lockRepoPair(a, b *Repo) (unlock func()) {
+	if a == b {
+		a.mu.Lock()
+		return a.mu.Unlock
+	}
+
+	first, second := a, b
+	if reflect.ValueOf(a).Pointer() > reflect.ValueOf(b).Pointer() {
+		first, second = b, a
+	}
+
+	first.mu.Lock()
+	second.mu.Lock()
+	return func() {
+		second.mu.Unlock()
+		first.mu.Unlock()
+	}
+}
+
+// readCopyProgressEntries decodes the newline-delimited JSON restic
+// writes to stdout/stderr during a copy, invoking progressCallback for
+// each entry.
+func readCopyProgressEntries(r io.Reader, progressCallback func(*CopyProgressEntry)) error {
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		var entry CopyProgressEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return fmt.Errorf("decoding copy progress entry: %w", err)
+		}
+		if progressCallback != nil {
+			progressCallback(&entry)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package restic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockRepoPairOrdering(t *testing.T) {
+	a := &Repo{}
+	b := &Repo{}
+
+	// Whichever order lockRepoPair is called in, it locks the same pair of
+	// mutexes: lockRepoPair(b, a) must wait while lockRepoPair(a, b) holds
+	// them and acquire them once they are released. This does not observe
+	// the order in which the two mutexes are taken.
+	unlock1 := lockRepoPair(a, b)
+	unlock2Done := make(chan struct{})
+	go func() {
+		unlock := lockRepoPair(b, a)
+		unlock()
+		close(unlock2Done)
+	}()
+
+	select {
+	case <-unlock2Done:
+		t.Fatal("lockRepoPair(b, a) acquired both locks while lockRepoPair(a, b) still held them")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock1()
+
+	select {
+	case <-unlock2Done:
+	case <-time.After(time.Second):
+		t.Fatal("lockRepoPair(b, a) did not acquire the locks after lockRepoPair(a, b) released them")
+	}
+}
+
+func TestLockRepoPairSameRepo(t *testing.T) {
+	a := &Repo{}
+
+	unlock := lockRepoPair(a, a)
+
+	done := make(chan struct{})
+	go func() {
+		a.mu.Lock()
+		a.mu.Unlock()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("a.mu was not held while lockRepoPair(a, a) was outstanding")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("a.mu was not released after lockRepoPair(a, a)'s unlock")
+	}
+}
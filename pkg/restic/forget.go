@@ -0,0 +1,272 @@
+package restic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// RetentionPolicy maps to the `restic forget --keep-*` family of flags.
+// Zero-valued fields are omitted from the generated command line.
+type RetentionPolicy struct {
+	KeepLast    int
+	KeepHourly  int
+	KeepDaily   int
+	KeepWeekly  int
+	KeepMonthly int
+	KeepYearly  int
+	KeepWithin  string
+	KeepTags    []string
+}
+
+func (r RetentionPolicy) toArgs() []string {
+	var args []string
+	if r.KeepLast > 0 {
+		args = append(args, "--keep-last", fmt.Sprint(r.KeepLast))
+	}
+	if r.KeepHourly > 0 {
+		args = append(args, "--keep-hourly", fmt.Sprint(r.KeepHourly))
+	}
+	if r.KeepDaily > 0 {
+		args = append(args, "--keep-daily", fmt.Sprint(r.KeepDaily))
+	}
+	if r.KeepWeekly > 0 {
+		args = append(args, "--keep-weekly", fmt.Sprint(r.KeepWeekly))
+	}
+	if r.KeepMonthly > 0 {
+		args = append(args, "--keep-monthly", fmt.Sprint(r.KeepMonthly))
+	}
+	if r.KeepYearly > 0 {
+		args = append(args, "--keep-yearly", fmt.Sprint(r.KeepYearly))
+	}
+	if r.KeepWithin != "" {
+		args = append(args, "--keep-within", r.KeepWithin)
+	}
+	for _, tag := range r.KeepTags {
+		args = append(args, "--keep-tag", tag)
+	}
+	return args
+}
+
+// ForgetReason describes why a snapshot was kept or removed by a forget
+// policy, as reported in the `reasons` section of `restic forget --json`.
+type ForgetReason struct {
+	Snapshot *Snapshot      `json:"snapshot"`
+	Matches  []string       `json:"matches"`
+	Counters map[string]int `json:"counters"`
+}
+
+// ForgetResult is one entry of the array restic emits for `forget --json`,
+// grouped by the host/paths/tags the policy was applied to.
+type ForgetResult struct {
+	Tags    []string        `json:"tags"`
+	Host    string          `json:"host"`
+	Paths   []string        `json:"paths"`
+	Keep    []*Snapshot     `json:"keep"`
+	Remove  []*Snapshot     `json:"remove"`
+	Reasons []*ForgetReason `json:"reasons"`
+}
+
+type ForgetOpts struct {
+	policy     RetentionPolicy
+	snapshotID string
+	prune      bool
+	extraArgs  []string
+}
+
+type ForgetOption func(opts *ForgetOpts)
+
+// WithForgetSnapshot forgets a specific snapshot by ID rather than applying
+// a retention policy.
+func WithForgetSnapshot(id string) ForgetOption {
+	return func(opts *ForgetOpts) {
+		opts.snapshotID = id
+	}
+}
+
+// WithForgetPrune passes `--prune` so restic reclaims space for the removed
+// snapshots immediately rather than requiring a separate Prune call.
+func WithForgetPrune() ForgetOption {
+	return func(opts *ForgetOpts) {
+		opts.prune = true
+	}
+}
+
+func WithForgetHost(host string) ForgetOption {
+	return func(opts *ForgetOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--host", host)
+	}
+}
+
+func WithForgetTags(tags ...string) ForgetOption {
+	return func(opts *ForgetOpts) {
+		for _, tag := range tags {
+			opts.extraArgs = append(opts.extraArgs, "--tag", tag)
+		}
+	}
+}
+
+func WithForgetPath(path string) ForgetOption {
+	return func(opts *ForgetOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--path", path)
+	}
+}
+
+// Forget applies a retention policy (or removes a specific snapshot via
+// WithForgetSnapshot) and returns the keep/remove decision restic made for
+// each matching group of snapshots.
+func (r *Repo) Forget(ctx context.Context, policy RetentionPolicy, opts ...ForgetOption) ([]*ForgetResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.init(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize repo: %w", err)
+	}
+
+	opt := &ForgetOpts{policy: policy}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	args := []string{"forget", "--json"}
+	args = append(args, r.extraArgs...)
+	if opt.snapshotID != "" {
+		args = append(args, opt.snapshotID)
+	} else {
+		args = append(args, opt.policy.toArgs()...)
+	}
+	if opt.prune {
+		args = append(args, "--prune")
+	}
+	args = append(args, opt.extraArgs...)
+
+	cmd := exec.CommandContext(ctx, r.cmd, args...)
+	cmd.Env = append(cmd.Env, r.buildEnv()...)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, NewCmdError(cmd, output, err)
+	}
+
+	results, err := readForgetResult(output)
+	if err != nil {
+		return nil, NewCmdError(cmd, output, err)
+	}
+
+	return results, nil
+}
+
+// readForgetResult decodes the output of `restic forget --json`. Normally
+// this is exactly one JSON array of ForgetResult groups, but when
+// WithForgetPrune is set restic runs prune inline afterwards and appends
+// its own newline-delimited JSON stream (the same "status"/"summary"
+// messages readPruneResult parses) to the same output. A Decoder reads
+// only the first top-level JSON value off the stream, so the trailing
+// prune messages are simply left unread rather than tripping a strict
+// json.Unmarshal on the whole buffer.
+func readForgetResult(output []byte) ([]*ForgetResult, error) {
+	decoder := json.NewDecoder(bytes.NewReader(output))
+
+	var results []*ForgetResult
+	if err := decoder.Decode(&results); err != nil {
+		return nil, fmt.Errorf("command output is not valid JSON: %w", err)
+	}
+
+	return results, nil
+}
+
+// PruneResult summarizes the `restic prune --json` summary line.
+type PruneResult struct {
+	TotalPackedSizeBefore int64 `json:"total_packed_size_before"`
+	TotalBlobsBefore      int64 `json:"total_blobs_before"`
+	TotalSizeBefore       int64 `json:"total_size_before"`
+	TotalSizeAfter        int64 `json:"total_size_after"`
+	BytesRemoved          int64 `json:"bytes_removed"`
+	PacksRemoved          int64 `json:"packs_removed"`
+}
+
+type PruneOpts struct {
+	extraArgs []string
+}
+
+type PruneOption func(opts *PruneOpts)
+
+func WithPruneMaxUnused(limit string) PruneOption {
+	return func(opts *PruneOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--max-unused", limit)
+	}
+}
+
+// Prune removes unreferenced data from the repository, reclaiming space for
+// snapshots previously forgotten. This is typically run after Forget unless
+// WithForgetPrune was used.
+func (r *Repo) Prune(ctx context.Context, opts ...PruneOption) (*PruneResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.init(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize repo: %w", err)
+	}
+
+	opt := &PruneOpts{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	args := []string{"prune", "--json"}
+	args = append(args, r.extraArgs...)
+	args = append(args, opt.extraArgs...)
+
+	lockCtx, lockCancel := r.watchLock(ctx)
+
+	cmd := exec.CommandContext(lockCtx, r.cmd, args...)
+	cmd.Env = append(cmd.Env, r.buildEnv()...)
+
+	output, err := r.runWithLockWatch(lockCtx, lockCancel, cmd)
+	if err != nil {
+		return nil, NewCmdError(cmd, output, err)
+	}
+
+	result, err := readPruneResult(output)
+	if err != nil {
+		return nil, NewCmdError(cmd, output, err)
+	}
+
+	return result, nil
+}
+
+// readPruneResult scans the newline-delimited JSON messages emitted by
+// `restic prune --json` and returns the final summary message.
+func readPruneResult(output []byte) (*PruneResult, error) {
+	var result *PruneResult
+
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for decoder.More() {
+		var msg struct {
+			MessageType string `json:"message_type"`
+		}
+		raw := json.RawMessage{}
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("decoding prune output: %w", err)
+		}
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			continue
+		}
+		if msg.MessageType != "summary" {
+			continue
+		}
+		var summary PruneResult
+		if err := json.Unmarshal(raw, &summary); err != nil {
+			return nil, fmt.Errorf("decoding prune summary: %w", err)
+		}
+		result = &summary
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("no summary message found in prune output")
+	}
+
+	return result, nil
+}
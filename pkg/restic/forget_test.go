@@ -0,0 +1,45 @@
+package restic
+
+import (
+	"testing"
+)
+
+func TestReadForgetResult(t *testing.T) {
+	output := []byte(`[{"tags":null,"host":"box","paths":["/data"],"keep":[{"id":"abc"}],"remove":[{"id":"def"}],"reasons":[{"snapshot":{"id":"def"},"matches":["daily snapshot"],"counters":{"daily":1}}]}]`)
+
+	results, err := readForgetResult(output)
+	if err != nil {
+		t.Fatalf("readForgetResult() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("len(results) = %d, want 1", len(results))
+	}
+	if results[0].Host != "box" {
+		t.Errorf("results[0].Host = %q, want %q", results[0].Host, "box")
+	}
+	if len(results[0].Remove) != 1 || results[0].Remove[0].ID != "def" {
+		t.Errorf("results[0].Remove = %+v, want one snapshot with id %q", results[0].Remove, "def")
+	}
+}
+
+// TestReadForgetResultWithPrune exercises the output shape produced when
+// WithForgetPrune is set: the forget array is followed by the NDJSON
+// stream restic's inline prune step emits. readForgetResult must return
+// the forget array and not fail on the trailing prune messages.
+func TestReadForgetResultWithPrune(t *testing.T) {
+	output := []byte(`[{"tags":null,"host":"box","paths":["/data"],"keep":[],"remove":[{"id":"def"}],"reasons":[]}]
+{"message_type":"status","percent_done":0.5}
+{"message_type":"summary","total_packed_size_before":100,"total_blobs_before":10,"total_size_before":100,"total_size_after":50,"bytes_removed":50,"packs_removed":1}
+`)
+
+	results, err := readForgetResult(output)
+	if err != nil {
+		t.Fatalf("readForgetResult() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("len(results) = %d, want 1", len(results))
+	}
+	if len(results[0].Remove) != 1 || results[0].Remove[0].ID != "def" {
+		t.Errorf("results[0].Remove = %+v, want one snapshot with id %q", results[0].Remove, "def")
+	}
+}
@@ -0,0 +1,267 @@
+package restic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// CheckResult summarizes `restic check --json`. Errors and warnings are
+// accumulated from the stream of `error`-typed messages restic emits; an
+// empty Errors slice means the repository passed all checks.
+type CheckResult struct {
+	Errors    []string `json:"-"`
+	NumErrors int      `json:"-"`
+}
+
+type CheckOpts struct {
+	extraArgs []string
+}
+
+type CheckOption func(opts *CheckOpts)
+
+// WithCheckReadData passes `--read-data`, verifying the integrity of all
+// repository data, not just the metadata.
+func WithCheckReadData() CheckOption {
+	return func(opts *CheckOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--read-data")
+	}
+}
+
+// WithCheckReadDataSubset verifies only an n/m subset of the repository's
+// data packs, equivalent to `restic check --read-data-subset n/m`.
+func WithCheckReadDataSubset(n int, m int) CheckOption {
+	return func(opts *CheckOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--read-data-subset", fmt.Sprintf("%d/%d", n, m))
+	}
+}
+
+// Check runs a repository consistency check and returns the errors restic
+// found, if any.
+func (r *Repo) Check(ctx context.Context, opts ...CheckOption) (*CheckResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.init(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize repo: %w", err)
+	}
+
+	opt := &CheckOpts{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	args := []string{"check", "--json"}
+	args = append(args, r.extraArgs...)
+	args = append(args, opt.extraArgs...)
+
+	lockCtx, lockCancel := r.watchLock(ctx)
+
+	cmd := exec.CommandContext(lockCtx, r.cmd, args...)
+	cmd.Env = append(cmd.Env, r.buildEnv()...)
+
+	output, err := r.runWithLockWatch(lockCtx, lockCancel, cmd)
+
+	result := &CheckResult{}
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for decoder.More() {
+		var msg struct {
+			MessageType string `json:"message_type"`
+			Message     string `json:"message"`
+		}
+		if decErr := decoder.Decode(&msg); decErr != nil {
+			break
+		}
+		if msg.MessageType == "error" {
+			result.Errors = append(result.Errors, msg.Message)
+		}
+	}
+	result.NumErrors = len(result.Errors)
+
+	if err != nil {
+		return result, NewCmdError(cmd, output, err)
+	}
+
+	return result, nil
+}
+
+// RepoStats summarizes `restic stats --json`.
+type RepoStats struct {
+	TotalSize      int64 `json:"total_size"`
+	TotalFileCount int64 `json:"total_file_count"`
+	TotalBlobCount int64 `json:"total_blob_count"`
+}
+
+type StatsMode string
+
+const (
+	StatsModeRestoreSize     StatsMode = "restore-size"
+	StatsModeFilesByContents StatsMode = "files-by-contents"
+	StatsModeRawData         StatsMode = "raw-data"
+	StatsModeBlobsPerFile    StatsMode = "blobs-per-file"
+)
+
+type StatsOpts struct {
+	mode      StatsMode
+	extraArgs []string
+}
+
+type StatsOption func(opts *StatsOpts)
+
+// WithStatsMode selects the counting mode restic uses when computing
+// repository statistics, equivalent to `restic stats --mode`.
+func WithStatsMode(mode StatsMode) StatsOption {
+	return func(opts *StatsOpts) {
+		opts.mode = mode
+	}
+}
+
+func WithStatsHost(host string) StatsOption {
+	return func(opts *StatsOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--host", host)
+	}
+}
+
+func WithStatsTags(tags ...string) StatsOption {
+	return func(opts *StatsOpts) {
+		for _, tag := range tags {
+			opts.extraArgs = append(opts.extraArgs, "--tag", tag)
+		}
+	}
+}
+
+// Stats computes size and count statistics for the repository, or the
+// snapshots matched by the provided filter options.
+func (r *Repo) Stats(ctx context.Context, opts ...StatsOption) (*RepoStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.init(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize repo: %w", err)
+	}
+
+	opt := &StatsOpts{mode: StatsModeRestoreSize}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	args := []string{"stats", "--json", "--mode", string(opt.mode)}
+	args = append(args, r.extraArgs...)
+	args = append(args, opt.extraArgs...)
+
+	cmd := exec.CommandContext(ctx, r.cmd, args...)
+	cmd.Env = append(cmd.Env, r.buildEnv()...)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, NewCmdError(cmd, output, err)
+	}
+
+	var stats RepoStats
+	if err := json.Unmarshal(output, &stats); err != nil {
+		return nil, NewCmdError(cmd, output, fmt.Errorf("command output is not valid JSON: %w", err))
+	}
+
+	return &stats, nil
+}
+
+// DiffResult summarizes the path-level changes between two snapshots, as
+// reported by the stream of `change` messages from `restic diff --json`.
+type DiffResult struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+
+	SourceChanged int64 `json:"source_changed"`
+	SourceAdded   int64 `json:"source_added"`
+	SourceRemoved int64 `json:"source_removed"`
+}
+
+type DiffOpts struct {
+	extraArgs []string
+}
+
+type DiffOption func(opts *DiffOpts)
+
+func WithDiffMetadata() DiffOption {
+	return func(opts *DiffOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--metadata")
+	}
+}
+
+// Diff compares two snapshots and returns the set of paths that were
+// added, removed, or changed between them.
+func (r *Repo) Diff(ctx context.Context, snapshotA string, snapshotB string, opts ...DiffOption) (*DiffResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.init(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize repo: %w", err)
+	}
+
+	opt := &DiffOpts{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	args := []string{"diff", "--json", snapshotA, snapshotB}
+	args = append(args, r.extraArgs...)
+	args = append(args, opt.extraArgs...)
+
+	cmd := exec.CommandContext(ctx, r.cmd, args...)
+	cmd.Env = append(cmd.Env, r.buildEnv()...)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, NewCmdError(cmd, output, err)
+	}
+
+	result, err := readDiffResult(output)
+	if err != nil {
+		return nil, NewCmdError(cmd, output, err)
+	}
+
+	return result, nil
+}
+
+// readDiffResult decodes the newline-delimited JSON restic writes for
+// `diff --json`: a stream of `change` messages followed by a final
+// `statistics` message.
+func readDiffResult(output []byte) (*DiffResult, error) {
+	result := &DiffResult{}
+
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for decoder.More() {
+		var msg struct {
+			MessageType   string `json:"message_type"`
+			Path          string `json:"path"`
+			Modifier      string `json:"modifier"`
+			SourceChanged int64  `json:"source_changed"`
+			SourceAdded   int64  `json:"source_added"`
+			SourceRemoved int64  `json:"source_removed"`
+		}
+		if err := decoder.Decode(&msg); err != nil {
+			return nil, fmt.Errorf("decoding diff output: %w", err)
+		}
+
+		switch msg.MessageType {
+		case "change":
+			switch msg.Modifier {
+			case "+":
+				result.Added = append(result.Added, msg.Path)
+			case "-":
+				result.Removed = append(result.Removed, msg.Path)
+			default:
+				result.Changed = append(result.Changed, msg.Path)
+			}
+		case "statistics":
+			result.SourceChanged = msg.SourceChanged
+			result.SourceAdded = msg.SourceAdded
+			result.SourceRemoved = msg.SourceRemoved
+		}
+	}
+
+	return result, nil
+}
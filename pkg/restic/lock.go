@@ -0,0 +1,244 @@
+package restic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultLockRefreshInterval = 5 * time.Minute
+	defaultStaleLockAge        = 30 * time.Minute
+)
+
+// WithLockRefreshInterval overrides how often the lock watchdog checks
+// `restic list locks` for long-running operations on this Repo. Defaults
+// to 5 minutes.
+func WithLockRefreshInterval(interval time.Duration) GenericOption {
+	return func(opts *GenericOpts) {
+		opts.lockRefreshInterval = interval
+	}
+}
+
+// ErrStaleLock is returned (wrapped) when the lock watchdog observes that
+// this operation's repository lock has disappeared or gone stale and
+// cancels the in-flight restic command as a result. Callers can use
+// errors.Is(err, ErrStaleLock) to distinguish this from a generic command
+// failure.
+var ErrStaleLock = errors.New("restic: repository lock is stale or missing")
+
+// lock mirrors one entry of `restic list locks --json`: the full lock
+// object restic writes into the repo, including the process that holds
+// it. Hostname+PID is what lets the watchdog tell "our" lock apart from
+// another client's lock on the same repository.
+type lock struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	Hostname string    `json:"hostname"`
+	PID      int       `json:"pid"`
+}
+
+// watchLock derives a cancellable context from parent for a long-running
+// restic invocation. Use the returned context to build the *exec.Cmd, then
+// call startLockWatchdog with the started process's PID once cmd.Start()
+// returns, before cmd.Wait() is awaited.
+func (r *Repo) watchLock(parent context.Context) (ctx context.Context, cancel context.CancelFunc) {
+	return context.WithCancel(parent)
+}
+
+// startLockWatchdog starts a goroutine that periodically lists the
+// repository's locks and looks for the one held by pid (the restic
+// process started on ctx's command). If that specific lock disappears or
+// goes stale (time older than 30 min), or is still not listed by the
+// second listing, it cancels the operation via
+// cancel so the wrapped restic process exits cleanly rather than
+// continuing to write to a repo another client believes is unlocked.
+// Locks held by other hosts/processes are ignored, so a concurrent
+// client's actively-refreshed lock can never mask the loss of our own.
+//
+// It returns a finish func that must be called with the command's
+// resulting error once the operation completes; finish stops the
+// goroutine and rewraps err as ErrStaleLock if the watchdog triggered the
+// cancellation. finish may be called more than once; only the first call
+// stops the goroutine.
+func (r *Repo) startLockWatchdog(ctx context.Context, cancel context.CancelFunc, pid int) (finish func(err error) error) {
+	interval := r.lockRefreshInterval
+	if interval <= 0 {
+		interval = defaultLockRefreshInterval
+	}
+
+	hostname, _ := os.Hostname()
+
+	return startLockWatchdogWithLister(ctx, cancel, interval, hostname, pid, r.listLocks)
+}
+
+// startLockWatchdogWithLister is startLockWatchdog's implementation, with
+// the hostname, refresh interval, and `restic list locks` call factored
+// out as parameters so tests can exercise the stale/missing-lock
+// detection logic against a fake listLocks without a real restic binary.
+func startLockWatchdogWithLister(ctx context.Context, cancel context.CancelFunc, interval time.Duration, hostname string, pid int, listLocks func(ctx context.Context) ([]*lock, error)) (finish func(err error) error) {
+	var triggered int32
+	done := make(chan struct{})
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		// seenOwnLock tracks whether we have ever observed our lock and
+		// listings counts successful listings, so that a slow first
+		// listing (the lock not yet visible) isn't mistaken for the lock
+		// having already disappeared. From the second listing on, a
+		// missing lock cancels the operation.
+		seenOwnLock := false
+		listings := 0
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				locks, err := listLocks(ctx)
+				if err != nil {
+					// a transient error listing locks shouldn't trip the
+					// watchdog; only an observed stale/missing lock does.
+					continue
+				}
+
+				listings++
+				own := findOwnLock(locks, hostname, pid)
+				if own == nil {
+					if seenOwnLock || listings > 1 {
+						atomic.StoreInt32(&triggered, 1)
+						cancel()
+						return
+					}
+					continue
+				}
+
+				seenOwnLock = true
+				if time.Since(own.Time) > defaultStaleLockAge {
+					atomic.StoreInt32(&triggered, 1)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	var finishOnce sync.Once
+	finish = func(err error) error {
+		finishOnce.Do(func() { close(done) })
+		cancel()
+		if err != nil && atomic.LoadInt32(&triggered) == 1 {
+			return fmt.Errorf("%w: %v", ErrStaleLock, err)
+		}
+		return err
+	}
+
+	return finish
+}
+
+// runWithLockWatch starts cmd, which must have been constructed with the
+// context returned by r.watchLock, watches its lock via startLockWatchdog,
+// waits for it to complete, and returns its combined stdout+stderr
+// output. It is the CombinedOutput() equivalent for long-running
+// operations that need the watchdog wired up, since the watchdog needs
+// the process's PID, which is only available after Start().
+func (r *Repo) runWithLockWatch(lockCtx context.Context, lockCancel context.CancelFunc, cmd *exec.Cmd) ([]byte, error) {
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := cmd.Start(); err != nil {
+		lockCancel()
+		return nil, err
+	}
+
+	finish := r.startLockWatchdog(lockCtx, lockCancel, cmd.Process.Pid)
+
+	err := finish(cmd.Wait())
+	return output.Bytes(), err
+}
+
+func findOwnLock(locks []*lock, hostname string, pid int) *lock {
+	for _, l := range locks {
+		if l.PID == pid && l.Hostname == hostname {
+			return l
+		}
+	}
+	return nil
+}
+
+func (r *Repo) listLocks(ctx context.Context) ([]*lock, error) {
+	args := []string{"list", "locks", "--json"}
+	args = append(args, r.extraArgs...)
+
+	cmd := exec.CommandContext(ctx, r.cmd, args...)
+	cmd.Env = append(cmd.Env, r.buildEnv()...)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, NewCmdError(cmd, output, err)
+	}
+
+	var locks []*lock
+	if err := json.Unmarshal(output, &locks); err != nil {
+		return nil, fmt.Errorf("command output is not valid JSON: %w", err)
+	}
+
+	return locks, nil
+}
+
+type UnlockOpts struct {
+	removeAll bool
+}
+
+type UnlockOption func(opts *UnlockOpts)
+
+// WithRemoveAllLocks passes `--remove-all`, removing every lock in the
+// repository rather than only stale ones.
+func WithRemoveAllLocks() UnlockOption {
+	return func(opts *UnlockOpts) {
+		opts.removeAll = true
+	}
+}
+
+// Unlock removes stale locks from the repository, equivalent to
+// `restic unlock`.
+func (r *Repo) Unlock(ctx context.Context, opts ...UnlockOption) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.init(ctx); err != nil {
+		return fmt.Errorf("failed to initialize repo: %w", err)
+	}
+
+	opt := &UnlockOpts{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	args := []string{"unlock"}
+	if opt.removeAll {
+		args = append(args, "--remove-all")
+	}
+	args = append(args, r.extraArgs...)
+
+	cmd := exec.CommandContext(ctx, r.cmd, args...)
+	cmd.Env = append(cmd.Env, r.buildEnv()...)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return NewCmdError(cmd, output, err)
+	}
+
+	return nil
+}
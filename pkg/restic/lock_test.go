@@ -0,0 +1,99 @@
+package restic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStartLockWatchdogCancelsOnMissingLock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	listLocks := func(context.Context) ([]*lock, error) {
+		calls++
+		if calls == 1 {
+			return []*lock{{ID: "own", Hostname: "host", PID: 123, Time: time.Now()}}, nil
+		}
+		// our lock has disappeared from the listing entirely
+		return nil, nil
+	}
+
+	finish := startLockWatchdogWithLister(ctx, cancel, 10*time.Millisecond, "host", 123, listLocks)
+	defer finish(nil)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not cancel the context after our lock disappeared")
+	}
+
+	err := finish(errors.New("command failed"))
+	if !errors.Is(err, ErrStaleLock) {
+		t.Errorf("finish(err) = %v, want wrapped ErrStaleLock", err)
+	}
+}
+
+func TestStartLockWatchdogCancelsOnStaleLock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listLocks := func(context.Context) ([]*lock, error) {
+		return []*lock{{ID: "own", Hostname: "host", PID: 123, Time: time.Now().Add(-time.Hour)}}, nil
+	}
+
+	finish := startLockWatchdogWithLister(ctx, cancel, 10*time.Millisecond, "host", 123, listLocks)
+	defer finish(nil)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not cancel the context after our lock went stale")
+	}
+
+	err := finish(errors.New("command failed"))
+	if !errors.Is(err, ErrStaleLock) {
+		t.Errorf("finish(err) = %v, want wrapped ErrStaleLock", err)
+	}
+}
+
+func TestStartLockWatchdogIgnoresOtherClientsLocks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Another client's actively-refreshed lock must not mask the loss of
+	// our own lock: every listing includes only a fresh lock belonging to
+	// a different host/pid, never ours.
+	listLocks := func(context.Context) ([]*lock, error) {
+		return []*lock{{ID: "other", Hostname: "otherhost", PID: 999, Time: time.Now()}}, nil
+	}
+
+	finish := startLockWatchdogWithLister(ctx, cancel, 10*time.Millisecond, "host", 123, listLocks)
+	defer finish(nil)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("watchdog did not cancel after our lock was never observed while another client's lock stayed fresh")
+	}
+}
+
+func TestStartLockWatchdogFinishStopsGoroutine(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listLocks := func(context.Context) ([]*lock, error) {
+		return []*lock{{ID: "own", Hostname: "host", PID: 123, Time: time.Now()}}, nil
+	}
+
+	finish := startLockWatchdogWithLister(ctx, cancel, 10*time.Millisecond, "host", 123, listLocks)
+
+	// finish on a non-triggered watchdog must not wrap a real command error
+	// as a stale lock, and must stop the polling goroutine cleanly.
+	cmdErr := errors.New("command failed")
+	if err := finish(cmdErr); !errors.Is(err, cmdErr) || errors.Is(err, ErrStaleLock) {
+		t.Errorf("finish(err) = %v, want %v unwrapped (watchdog never triggered)", err, cmdErr)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"sync"
+	"time"
 
 	v1 "github.com/garethgeorge/resticui/gen/go/v1"
 	"github.com/hashicorp/go-multierror"
@@ -23,6 +24,7 @@ type Repo struct {
 
 	extraArgs []string
 	extraEnv []string
+	lockRefreshInterval time.Duration
 }
 
 func NewRepo(repo *v1.Repo, opts ...GenericOption) *Repo {
@@ -37,6 +39,7 @@ func NewRepo(repo *v1.Repo, opts ...GenericOption) *Repo {
 		initialized: false,
 		extraArgs: opt.extraArgs,
 		extraEnv: opt.extraEnv,
+		lockRefreshInterval: opt.lockRefreshInterval,
 	}
 }
 
@@ -90,33 +93,50 @@ func (r *Repo) Backup(ctx context.Context, progressCallback func(*BackupProgress
 		o(opt)
 	}
 
-	for _, p := range opt.paths {
-		if _, err := os.Stat(p); err != nil {
-			return nil, fmt.Errorf("path %s does not exist: %w", p, err)
+	if opt.stdin != nil {
+		if len(opt.paths) > 0 {
+			return nil, fmt.Errorf("paths must not be set when using WithBackupStdin")
+		}
+	} else {
+		for _, p := range opt.paths {
+			if _, err := os.Stat(p); err != nil {
+				return nil, fmt.Errorf("path %s does not exist: %w", p, err)
+			}
 		}
 	}
 
 	args := []string{"backup", "--json", "--exclude-caches"}
 	args = append(args, r.extraArgs...)
+	if opt.stdin != nil {
+		args = append(args, "--stdin", "--stdin-filename", opt.stdinFilename)
+	}
 	args = append(args, opt.paths...)
 	args = append(args, opt.extraArgs...)
 
 	reader, writer := io.Pipe()
 
-	cmd := exec.CommandContext(ctx, r.cmd, args...)
+	lockCtx, lockCancel := r.watchLock(ctx)
+
+	cmd := exec.CommandContext(lockCtx, r.cmd, args...)
 	cmd.Env = append(cmd.Env, r.buildEnv()...)
 	cmd.Stderr = writer
 	cmd.Stdout = writer
+	if opt.stdin != nil {
+		cmd.Stdin = opt.stdin
+	}
 
 	if err := cmd.Start(); err != nil {
+		lockCancel()
 		return nil, NewCmdError(cmd, nil, err)
 	}
-	
+
+	finishLockWatch := r.startLockWatchdog(lockCtx, lockCancel, cmd.Process.Pid)
+
 	var wg sync.WaitGroup
 	var summary *BackupProgressEntry
-	var cmdErr error 
+	var cmdErr error
 	var readErr error
-	
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -132,12 +152,14 @@ func (r *Repo) Backup(ctx context.Context, progressCallback func(*BackupProgress
 		defer writer.Close()
 		defer wg.Done()
 		if err := cmd.Wait(); err != nil {
-			cmdErr = NewCmdError(cmd, nil, err)
+			cmdErr = NewCmdError(cmd, nil, finishLockWatch(err))
+		} else {
+			finishLockWatch(nil)
 		}
 	}()
 
 	wg.Wait()
-	
+
 	var err error
 	if cmdErr != nil || readErr != nil {
 		err = multierror.Append(nil, cmdErr, readErr)
@@ -216,6 +238,8 @@ func (r *Repo) ListDirectory(ctx context.Context, snapshot string, path string,
 type BackupOpts struct {
 	paths []string
 	extraArgs []string
+	stdin io.Reader
+	stdinFilename string
 }
 
 type BackupOption func(opts *BackupOpts)
@@ -226,6 +250,17 @@ func WithBackupPaths(paths ...string) BackupOption {
 	}
 }
 
+// WithBackupStdin backs up the contents of r as a single file named
+// filename instead of reading paths from disk, equivalent to
+// `restic backup --stdin --stdin-filename <filename>`. It is mutually
+// exclusive with WithBackupPaths.
+func WithBackupStdin(r io.Reader, filename string) BackupOption {
+	return func(opts *BackupOpts) {
+		opts.stdin = r
+		opts.stdinFilename = filename
+	}
+}
+
 func WithBackupExcludes(excludes ...string) BackupOption {
 	return func(opts *BackupOpts) {
 		for _, exclude := range excludes {
@@ -242,9 +277,77 @@ func WithBackupTags(tags ...string) BackupOption {
 	}
 }
 
+// WithBackupExcludeFile reads exclude patterns from the file at path,
+// equivalent to `restic backup --exclude-file`.
+func WithBackupExcludeFile(path string) BackupOption {
+	return func(opts *BackupOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--exclude-file", path)
+	}
+}
+
+// WithBackupIExcludes is the case-insensitive counterpart to
+// WithBackupExcludes, equivalent to `restic backup --iexclude`.
+func WithBackupIExcludes(patterns ...string) BackupOption {
+	return func(opts *BackupOpts) {
+		for _, pattern := range patterns {
+			opts.extraArgs = append(opts.extraArgs, "--iexclude", pattern)
+		}
+	}
+}
+
+// WithBackupExcludeLargerThan skips files larger than size (e.g. "1G"),
+// equivalent to `restic backup --exclude-larger-than`.
+func WithBackupExcludeLargerThan(size string) BackupOption {
+	return func(opts *BackupOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--exclude-larger-than", size)
+	}
+}
+
+// WithBackupOneFileSystem restricts the backup to the filesystem(s) the
+// given paths reside on, equivalent to `restic backup --one-file-system`.
+func WithBackupOneFileSystem() BackupOption {
+	return func(opts *BackupOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--one-file-system")
+	}
+}
+
+// WithBackupExcludeIfPresent skips any directory containing a file named
+// filename, equivalent to `restic backup --exclude-if-present`.
+func WithBackupExcludeIfPresent(filename string) BackupOption {
+	return func(opts *BackupOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--exclude-if-present", filename)
+	}
+}
+
+// WithBackupParent sets the parent snapshot to diff against, equivalent
+// to `restic backup --parent`.
+func WithBackupParent(id string) BackupOption {
+	return func(opts *BackupOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--parent", id)
+	}
+}
+
+// WithBackupForce forces a full rescan of all files rather than relying
+// on the parent snapshot's cached metadata, equivalent to
+// `restic backup --force`.
+func WithBackupForce() BackupOption {
+	return func(opts *BackupOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--force")
+	}
+}
+
+// WithBackupHost overrides the hostname restic records for the snapshot,
+// equivalent to `restic backup --host`.
+func WithBackupHost(host string) BackupOption {
+	return func(opts *BackupOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--host", host)
+	}
+}
+
 type GenericOpts struct {
 	extraArgs []string
 	extraEnv []string
+	lockRefreshInterval time.Duration
 }
 
 func resolveOpts(opts []GenericOption) *GenericOpts {
@@ -0,0 +1,189 @@
+package restic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// RestoreProgressEntry mirrors the `status` / `summary` JSON lines restic
+// 0.15+ emits for `restic restore --json`.
+type RestoreProgressEntry struct {
+	MessageType string `json:"message_type"` // "status" or "summary"
+
+	// fields present when MessageType == "status"
+	SecondsElapsed float64 `json:"seconds_elapsed,omitempty"`
+	PercentDone    float64 `json:"percent_done,omitempty"`
+	TotalFiles     int64   `json:"total_files,omitempty"`
+	FilesRestored  int64   `json:"files_restored,omitempty"`
+	TotalBytes     int64   `json:"total_bytes,omitempty"`
+	BytesRestored  int64   `json:"bytes_restored,omitempty"`
+
+	// fields present when MessageType == "summary"
+	FilesSkipped  int64 `json:"files_skipped,omitempty"`
+	TotalRestored int64 `json:"total_restored,omitempty"`
+}
+
+type RestoreOpts struct {
+	target    string
+	includes  []string
+	excludes  []string
+	extraArgs []string
+}
+
+type RestoreOption func(opts *RestoreOpts)
+
+// WithRestoreTarget sets the directory to restore files into, equivalent to
+// `restic restore --target`.
+func WithRestoreTarget(path string) RestoreOption {
+	return func(opts *RestoreOpts) {
+		opts.target = path
+	}
+}
+
+func WithRestoreInclude(patterns ...string) RestoreOption {
+	return func(opts *RestoreOpts) {
+		opts.includes = append(opts.includes, patterns...)
+	}
+}
+
+func WithRestoreExclude(patterns ...string) RestoreOption {
+	return func(opts *RestoreOpts) {
+		opts.excludes = append(opts.excludes, patterns...)
+	}
+}
+
+func WithRestoreHost(host string) RestoreOption {
+	return func(opts *RestoreOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--host", host)
+	}
+}
+
+func WithRestorePath(path string) RestoreOption {
+	return func(opts *RestoreOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--path", path)
+	}
+}
+
+// WithRestoreVerify verifies the restored files' content against the
+// repository after restoring, equivalent to `restic restore --verify`.
+func WithRestoreVerify() RestoreOption {
+	return func(opts *RestoreOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--verify")
+	}
+}
+
+// Restore recovers files from snapshotID, streaming progress entries to
+// progressCallback as restic reports them. It follows the same pipe +
+// goroutine + multierror pattern as Backup.
+func (r *Repo) Restore(ctx context.Context, snapshotID string, progressCallback func(*RestoreProgressEntry), opts ...RestoreOption) (*RestoreProgressEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.init(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize repo: %w", err)
+	}
+
+	opt := &RestoreOpts{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	if opt.target == "" {
+		return nil, fmt.Errorf("restore target must be set via WithRestoreTarget")
+	}
+
+	args := []string{"restore", "--json", snapshotID, "--target", opt.target}
+	for _, include := range opt.includes {
+		args = append(args, "--include", include)
+	}
+	for _, exclude := range opt.excludes {
+		args = append(args, "--exclude", exclude)
+	}
+	args = append(args, r.extraArgs...)
+	args = append(args, opt.extraArgs...)
+
+	reader, writer := io.Pipe()
+
+	lockCtx, lockCancel := r.watchLock(ctx)
+
+	cmd := exec.CommandContext(lockCtx, r.cmd, args...)
+	cmd.Env = append(cmd.Env, r.buildEnv()...)
+	cmd.Stderr = writer
+	cmd.Stdout = writer
+
+	if err := cmd.Start(); err != nil {
+		lockCancel()
+		return nil, NewCmdError(cmd, nil, err)
+	}
+
+	finishLockWatch := r.startLockWatchdog(lockCtx, lockCancel, cmd.Process.Pid)
+
+	var wg sync.WaitGroup
+	var summary *RestoreProgressEntry
+	var cmdErr error
+	var readErr error
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var err error
+		summary, err = readRestoreProgressEntries(cmd, reader, progressCallback)
+		if err != nil {
+			readErr = fmt.Errorf("processing command output: %w", err)
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer writer.Close()
+		defer wg.Done()
+		if err := cmd.Wait(); err != nil {
+			cmdErr = NewCmdError(cmd, nil, finishLockWatch(err))
+		} else {
+			finishLockWatch(nil)
+		}
+	}()
+
+	wg.Wait()
+
+	var err error
+	if cmdErr != nil || readErr != nil {
+		err = multierror.Append(nil, cmdErr, readErr)
+	}
+	return summary, err
+}
+
+// readRestoreProgressEntries decodes the newline-delimited JSON restic
+// writes to stdout/stderr during a restore, invoking progressCallback for
+// each entry and returning the final summary entry.
+func readRestoreProgressEntries(cmd *exec.Cmd, r io.Reader, progressCallback func(*RestoreProgressEntry)) (*RestoreProgressEntry, error) {
+	var summary *RestoreProgressEntry
+
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		var entry RestoreProgressEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("decoding restore progress entry: %w", err)
+		}
+
+		if progressCallback != nil {
+			progressCallback(&entry)
+		}
+
+		if entry.MessageType == "summary" {
+			summary = &entry
+		}
+	}
+
+	if summary == nil {
+		return nil, fmt.Errorf("no summary message found in restore output")
+	}
+
+	return summary, nil
+}
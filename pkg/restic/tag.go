@@ -0,0 +1,128 @@
+package restic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// TagResult summarizes the `restic tag --json` summary message.
+type TagResult struct {
+	ChangedSnapshots int `json:"changed_snapshots"`
+}
+
+type TagOpts struct {
+	extraArgs []string
+}
+
+type TagOption func(opts *TagOpts)
+
+func WithAddTags(tags ...string) TagOption {
+	return func(opts *TagOpts) {
+		for _, tag := range tags {
+			opts.extraArgs = append(opts.extraArgs, "--add", tag)
+		}
+	}
+}
+
+func WithRemoveTags(tags ...string) TagOption {
+	return func(opts *TagOpts) {
+		for _, tag := range tags {
+			opts.extraArgs = append(opts.extraArgs, "--remove", tag)
+		}
+	}
+}
+
+func WithSetTags(tags ...string) TagOption {
+	return func(opts *TagOpts) {
+		for _, tag := range tags {
+			opts.extraArgs = append(opts.extraArgs, "--set", tag)
+		}
+	}
+}
+
+func WithHostFilter(host string) TagOption {
+	return func(opts *TagOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--host", host)
+	}
+}
+
+func WithPathFilter(path string) TagOption {
+	return func(opts *TagOpts) {
+		opts.extraArgs = append(opts.extraArgs, "--path", path)
+	}
+}
+
+func WithTagFilter(tags ...string) TagOption {
+	return func(opts *TagOpts) {
+		for _, tag := range tags {
+			opts.extraArgs = append(opts.extraArgs, "--tag", tag)
+		}
+	}
+}
+
+// Tag adds, removes, or replaces tags on the given snapshots (or all
+// snapshots matching the filter options, if snapshotIDs is empty),
+// equivalent to `restic tag --json`.
+func (r *Repo) Tag(ctx context.Context, snapshotIDs []string, opts ...TagOption) (*TagResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.init(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize repo: %w", err)
+	}
+
+	opt := &TagOpts{}
+	for _, o := range opts {
+		o(opt)
+	}
+
+	args := []string{"tag", "--json"}
+	args = append(args, opt.extraArgs...)
+	args = append(args, r.extraArgs...)
+	args = append(args, snapshotIDs...)
+
+	cmd := exec.CommandContext(ctx, r.cmd, args...)
+	cmd.Env = append(cmd.Env, r.buildEnv()...)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, NewCmdError(cmd, output, err)
+	}
+
+	result, err := readTagResult(output)
+	if err != nil {
+		return nil, NewCmdError(cmd, output, err)
+	}
+
+	return result, nil
+}
+
+// readTagResult scans the newline-delimited JSON messages emitted by
+// `restic tag --json` and returns the final summary message.
+func readTagResult(output []byte) (*TagResult, error) {
+	var result *TagResult
+
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for decoder.More() {
+		var msg struct {
+			MessageType      string `json:"message_type"`
+			ChangedSnapshots int    `json:"changed_snapshots"`
+		}
+		if err := decoder.Decode(&msg); err != nil {
+			return nil, fmt.Errorf("decoding tag output: %w", err)
+		}
+		if msg.MessageType != "summary" {
+			continue
+		}
+		result = &TagResult{ChangedSnapshots: msg.ChangedSnapshots}
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("no summary message found in tag output")
+	}
+
+	return result, nil
+}